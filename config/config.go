@@ -28,10 +28,17 @@ import (
 	cliConfig "github.com/docker/cli/cli/config"
 )
 
+// ProviderConfig configures a single named entry of the provider registry.
+type ProviderConfig struct {
+	Path string `json:"path"`
+}
+
 // Config points to scan provider's binary
 type Config struct {
-	Path  string `json:"path"`
-	Optin bool   `json:"optin"`
+	Path      string                    `json:"path"`
+	SBOMPath  string                    `json:"sbomPath"`
+	Providers map[string]ProviderConfig `json:"providers,omitempty"`
+	Optin     bool                      `json:"optin"`
 }
 
 // ReadConfigFile tries to read docker-scan configuration file that
@@ -18,10 +18,7 @@ package authentication
 
 import (
 	"crypto"
-	"encoding/json"
-	"errors"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"path/filepath"
 	"time"
@@ -40,21 +37,38 @@ const (
 //Authenticator logs on docker Hub and retrieves a DockerScanID
 // if the one stored locally has expired
 type Authenticator struct {
-	hub        hub.Client
-	tokensPath string
-	jwks       jose.JSONWebKeySet
+	hub   hub.Client
+	store TokenStore
+	jwks  jose.JSONWebKeySet
 }
 
 //NewAuthenticator returns an Authenticator
 // configured to run against Docker Hub prod or staging
 func NewAuthenticator(jwks jose.JSONWebKeySet, apiHubBaseURL string) *Authenticator {
+	tokensPath := filepath.Join(cliConfig.Dir(), "scan", "tokens.json")
 	return &Authenticator{
-		hub:        hub.Client{Domain: apiHubBaseURL},
-		tokensPath: filepath.Join(cliConfig.Dir(), "scan", "tokens.json"),
-		jwks:       jwks,
+		hub:   hub.Client{Domain: apiHubBaseURL},
+		store: resolveTokenStore(tokensPath, os.Stderr),
+		jwks:  jwks,
 	}
 }
 
+// resolveTokenStore picks a keychain-backed store when the local Docker CLI
+// configuration points at a credsStore, falling back to the legacy
+// plaintext file otherwise. Any tokens left over from the file store are
+// migrated into the keychain and the file is removed.
+func resolveTokenStore(tokensPath string, stderr *os.File) TokenStore {
+	configFile := cliConfig.LoadDefaultConfigFile(stderr)
+	if helper := configFile.CredentialsStore; helper != "" {
+		store := newKeychainTokenStore(helper)
+		if err := migrateFileTokens(tokensPath, store); err != nil {
+			fmt.Fprintf(stderr, "docker scan: could not migrate local tokens to the keychain: %s\n", err)
+		}
+		return store
+	}
+	return newFileTokenStore(tokensPath)
+}
+
 //GetToken checks the local DockerScanID content for expiry,
 // if expired it negotiates a new one on Docker Hub.
 func (a *Authenticator) GetToken(hubAuthConfig types.AuthConfig) (string, error) {
@@ -78,16 +92,22 @@ func (a *Authenticator) GetToken(hubAuthConfig types.AuthConfig) (string, error)
 	return token, nil
 }
 
-func (a *Authenticator) getLocalToken(hubAuthConfig types.AuthConfig) string {
-	buf, err := ioutil.ReadFile(a.tokensPath)
-	if errors.Is(err, os.ErrNotExist) {
-		return ""
-	}
-	tokens := map[string]string{}
-	if err := json.Unmarshal(buf, &tokens); err != nil {
-		return ""
+// RegistryAuthConfig resolves credentials for a registry hostname using the
+// credential helper configured in the local Docker CLI configuration file
+// (credsStore/credHelpers), rather than requiring an AuthConfig sourced from
+// the local daemon. This lets remote scans authenticate against any
+// registry the user is logged into, not just the one backing the active
+// daemon, including non-Hub registries such as ECR or ACR.
+func (a *Authenticator) RegistryAuthConfig(hostname string) (types.AuthConfig, error) {
+	configFile, err := cliConfig.Load(cliConfig.Dir())
+	if err != nil {
+		return types.AuthConfig{}, fmt.Errorf("could not load docker configuration file: %w", err)
 	}
-	return tokens[hubAuthConfig.Username]
+	return configFile.GetAuthConfig(hostname)
+}
+
+func (a *Authenticator) getLocalToken(hubAuthConfig types.AuthConfig) string {
+	return a.store.Get(hubAuthConfig.Username)
 }
 
 func (a *Authenticator) checkTokenValidity(token string) error {
@@ -145,26 +165,5 @@ func (a *Authenticator) negotiateScanIDToken(hubAuthConfig types.AuthConfig) (st
 }
 
 func (a *Authenticator) updateLocalToken(hubAuthConfig types.AuthConfig, token string) error {
-	stats, err := os.Stat(a.tokensPath)
-	mode := os.FileMode(0644)
-	if err != nil {
-		if !errors.Is(err, os.ErrNotExist) {
-			return err
-		}
-	} else {
-		mode = stats.Mode()
-	}
-
-	buf, err := ioutil.ReadFile(a.tokensPath)
-	if err != nil && !errors.Is(err, os.ErrNotExist) {
-		return err
-	}
-	tokens := map[string]string{}
-	_ = json.Unmarshal(buf, &tokens) // if an error occurs (invalid content), we just erase the content with a new map
-	tokens[hubAuthConfig.Username] = token
-	buf, err = json.Marshal(tokens)
-	if err != nil {
-		return err
-	}
-	return ioutil.WriteFile(a.tokensPath, buf, mode)
+	return a.store.Set(hubAuthConfig.Username, token)
 }
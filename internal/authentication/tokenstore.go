@@ -0,0 +1,144 @@
+/*
+   Copyright 2020 Docker Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package authentication
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/docker/docker-credential-helpers/client"
+	"github.com/docker/docker-credential-helpers/credentials"
+)
+
+// scanServerURLPrefix namespaces DockerScanID keychain entries under a URL
+// of their own so multiple Hub accounts coexist without clashing with
+// Docker Hub's own registry login entry.
+const scanServerURLPrefix = "https://scan.docker.io/"
+
+// TokenStore persists DockerScanID JWTs keyed by Hub username.
+type TokenStore interface {
+	Get(username string) string
+	Set(username string, token string) error
+}
+
+// fileTokenStore is the legacy, plaintext tokens.json-backed store. It is
+// kept as a fallback for setups with no credsStore configured.
+type fileTokenStore struct {
+	path string
+}
+
+func newFileTokenStore(path string) *fileTokenStore {
+	return &fileTokenStore{path: path}
+}
+
+func (s *fileTokenStore) Get(username string) string {
+	buf, err := ioutil.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ""
+	}
+	tokens := map[string]string{}
+	if err := json.Unmarshal(buf, &tokens); err != nil {
+		return ""
+	}
+	return tokens[username]
+}
+
+func (s *fileTokenStore) Set(username string, token string) error {
+	stats, err := os.Stat(s.path)
+	mode := os.FileMode(0644)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	} else {
+		mode = stats.Mode()
+	}
+
+	buf, err := ioutil.ReadFile(s.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	tokens := map[string]string{}
+	_ = json.Unmarshal(buf, &tokens) // if an error occurs (invalid content), we just erase the content with a new map
+	tokens[username] = token
+	buf, err = json.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.path, buf, mode)
+}
+
+// keychainTokenStore persists tokens in the OS-native credential store
+// (macOS keychain, Windows Credential Manager, Secret Service, pass...)
+// through the same docker-credential-helpers protocol the Docker CLI uses
+// for registry credentials.
+type keychainTokenStore struct {
+	program client.ProgramFunc
+}
+
+func newKeychainTokenStore(helperSuffix string) *keychainTokenStore {
+	return &keychainTokenStore{program: client.NewShellProgramFunc("docker-credential-" + helperSuffix)}
+}
+
+func (s *keychainTokenStore) serverURL(username string) string {
+	return scanServerURLPrefix + username
+}
+
+func (s *keychainTokenStore) Get(username string) string {
+	creds, err := client.Get(s.program, s.serverURL(username))
+	if err != nil {
+		return ""
+	}
+	return creds.Secret
+}
+
+func (s *keychainTokenStore) Set(username string, token string) error {
+	return client.Store(s.program, &credentials.Credentials{
+		ServerURL: s.serverURL(username),
+		Username:  username,
+		Secret:    token,
+	})
+}
+
+// migrateFileTokens imports any tokens left over in the legacy tokens.json
+// file into store, then deletes the file. It is a no-op if the file does
+// not exist.
+func migrateFileTokens(path string, store TokenStore) error {
+	buf, err := ioutil.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	tokens := map[string]string{}
+	if err := json.Unmarshal(buf, &tokens); err != nil {
+		// The legacy file is corrupt; there is nothing to migrate, but it
+		// still needs to go so we stop trying on every run.
+		return os.Remove(path)
+	}
+	for username, token := range tokens {
+		if err := store.Set(username, token); err != nil {
+			return fmt.Errorf("could not migrate token for %s to the keychain: %w", username, err)
+		}
+	}
+	return os.Remove(path)
+}
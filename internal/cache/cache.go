@@ -0,0 +1,64 @@
+/*
+   Copyright 2020 Docker Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package cache memoizes provider output (a serialized ScanResult or a raw
+// SBOM) keyed by image digest, so that re-scanning the same digest with the
+// same provider and options does not shell out again.
+package cache
+
+import (
+	"fmt"
+
+	digest "github.com/opencontainers/go-digest"
+)
+
+// DefaultTTL is the TTL the scan commands pass to Set when caching a result.
+const DefaultTTL = 24 * 60 * 60 // seconds, avoids importing time just for a constant
+
+// Key identifies a cached entry: the image digest plus the provider and
+// options tuple that produced it, since the same digest can be scanned by
+// different providers or with different options.
+type Key struct {
+	Digest          digest.Digest
+	Provider        string
+	ProviderVersion string
+	// Options is a caller-chosen, deterministic serialization of whatever
+	// scan options affect the result (e.g. "sbom:spdx-json" or "scan").
+	Options string
+}
+
+// id deterministically identifies Key for use as a cache entry name.
+func (k Key) id() string {
+	return digest.FromString(fmt.Sprintf("%s|%s|%s|%s", k.Provider, k.ProviderVersion, k.Digest, k.Options)).Encoded()
+}
+
+func (k Key) String() string {
+	return fmt.Sprintf("%s (%s %s, %s)", k.Digest, k.Provider, k.ProviderVersion, k.Options)
+}
+
+// ResultCache persists provider output keyed by Key.
+type ResultCache interface {
+	// Get returns the cached value for key, if any and not expired.
+	Get(key Key) ([]byte, bool)
+	// Set stores value for key, expiring after ttlSeconds.
+	Set(key Key, value []byte, ttlSeconds int) error
+	// List returns the keys of all non-expired entries.
+	List() ([]Key, error)
+	// Remove deletes the entry for key, if any.
+	Remove(key Key) error
+	// Prune deletes all expired entries and returns how many were removed.
+	Prune() (int, error)
+}
@@ -0,0 +1,158 @@
+/*
+   Copyright 2020 Docker Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// diskEntry is the on-disk representation of a cached entry, one JSON file
+// per entry under the cache directory. Value is base64-encoded by
+// encoding/json since it is a []byte field.
+type diskEntry struct {
+	Key       Key       `json:"key"`
+	Value     []byte    `json:"value"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// diskCache is a ResultCache backed by a JSON file per entry under dir,
+// named after the digest of the entry's Key so lookups don't need an index.
+type diskCache struct {
+	dir string
+}
+
+// NewDiskCache returns a ResultCache that persists entries as JSON files
+// under dir, creating it on first write.
+func NewDiskCache(dir string) ResultCache {
+	return &diskCache{dir: dir}
+}
+
+func (c *diskCache) path(key Key) string {
+	return filepath.Join(c.dir, key.id()+".json")
+}
+
+func (c *diskCache) Get(key Key) ([]byte, bool) {
+	e, err := c.read(c.path(key))
+	if err != nil || time.Now().After(e.ExpiresAt) {
+		return nil, false
+	}
+	return e.Value, true
+}
+
+func (c *diskCache) Set(key Key, value []byte, ttlSeconds int) error {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return err
+	}
+	e := diskEntry{Key: key, Value: value, ExpiresAt: time.Now().Add(time.Duration(ttlSeconds) * time.Second)}
+	buf, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.path(key), buf, 0644)
+}
+
+func (c *diskCache) List() ([]Key, error) {
+	entries, err := c.readAll()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	keys := make([]Key, 0, len(entries))
+	for _, e := range entries {
+		if now.After(e.ExpiresAt) {
+			continue
+		}
+		keys = append(keys, e.Key)
+	}
+	return keys, nil
+}
+
+func (c *diskCache) Remove(key Key) error {
+	err := os.Remove(c.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (c *diskCache) Prune() (int, error) {
+	files, err := ioutil.ReadDir(c.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	removed := 0
+	for _, f := range files {
+		if !strings.HasSuffix(f.Name(), ".json") {
+			continue
+		}
+		path := filepath.Join(c.dir, f.Name())
+		e, err := c.read(path)
+		if err != nil || now.After(e.ExpiresAt) {
+			if err := os.Remove(path); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+func (c *diskCache) read(path string) (diskEntry, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return diskEntry{}, err
+	}
+	var e diskEntry
+	if err := json.Unmarshal(buf, &e); err != nil {
+		return diskEntry{}, fmt.Errorf("could not parse cache entry %s: %w", path, err)
+	}
+	return e, nil
+}
+
+func (c *diskCache) readAll() ([]diskEntry, error) {
+	files, err := ioutil.ReadDir(c.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]diskEntry, 0, len(files))
+	for _, f := range files {
+		if !strings.HasSuffix(f.Name(), ".json") {
+			continue
+		}
+		e, err := c.read(filepath.Join(c.dir, f.Name()))
+		if err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
@@ -0,0 +1,97 @@
+/*
+   Copyright 2020 Docker Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	key       Key
+	value     []byte
+	expiresAt time.Time
+}
+
+// memoryCache is a process-local, non-persistent ResultCache. It is mostly
+// useful for tests and for short-lived invocations that scan the same
+// digest more than once.
+type memoryCache struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+// NewMemoryCache returns an empty in-memory ResultCache.
+func NewMemoryCache() ResultCache {
+	return &memoryCache{entries: map[string]memoryEntry{}}
+}
+
+func (c *memoryCache) Get(key Key) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key.id()]
+	if !ok || time.Now().After(e.expiresAt) {
+		return nil, false
+	}
+	return e.value, true
+}
+
+func (c *memoryCache) Set(key Key, value []byte, ttlSeconds int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key.id()] = memoryEntry{
+		key:       key,
+		value:     value,
+		expiresAt: time.Now().Add(time.Duration(ttlSeconds) * time.Second),
+	}
+	return nil
+}
+
+func (c *memoryCache) List() ([]Key, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	keys := make([]Key, 0, len(c.entries))
+	for _, e := range c.entries {
+		if now.After(e.expiresAt) {
+			continue
+		}
+		keys = append(keys, e.key)
+	}
+	return keys, nil
+}
+
+func (c *memoryCache) Remove(key Key) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key.id())
+	return nil
+}
+
+func (c *memoryCache) Prune() (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	removed := 0
+	for id, e := range c.entries {
+		if now.After(e.expiresAt) {
+			delete(c.entries, id)
+			removed++
+		}
+	}
+	return removed, nil
+}
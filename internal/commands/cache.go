@@ -0,0 +1,106 @@
+/*
+   Copyright 2020 Docker Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+
+	cliConfig "github.com/docker/cli/cli/config"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/spf13/cobra"
+
+	"github.com/docker/scan-cli-plugin/internal/cache"
+)
+
+// resultCache returns the disk-backed cache used to memoize scan and SBOM
+// results, under ${DOCKER_CONFIG}/scan/cache/results.
+func resultCache() cache.ResultCache {
+	return cache.NewDiskCache(filepath.Join(cliConfig.Dir(), "scan", "cache", "results"))
+}
+
+// newCacheCmd returns the "docker scan cache" command, which manages the
+// on-disk scan result cache independently of running a scan.
+func newCacheCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the scan result cache",
+	}
+	cmd.AddCommand(newCacheListCmd(), newCachePruneCmd(), newCacheRemoveCmd())
+	return cmd
+}
+
+func newCacheListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List cached scan results",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			keys, err := resultCache().List()
+			if err != nil {
+				return err
+			}
+			for _, k := range keys {
+				fmt.Fprintln(cmd.OutOrStdout(), k.String())
+			}
+			return nil
+		},
+	}
+}
+
+func newCachePruneCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "prune",
+		Short: "Remove expired entries from the scan result cache",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			removed, err := resultCache().Prune()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed %d expired entries\n", removed)
+			return nil
+		},
+	}
+}
+
+func newCacheRemoveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rm DIGEST PROVIDER",
+		Short: "Remove a cached scan result",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c := resultCache()
+			keys, err := c.List()
+			if err != nil {
+				return err
+			}
+			dgst := digest.Digest(args[0])
+			removed := 0
+			for _, key := range keys {
+				if key.Digest == dgst && key.Provider == args[1] {
+					if err := c.Remove(key); err != nil {
+						return err
+					}
+					removed++
+				}
+			}
+			if removed == 0 {
+				return fmt.Errorf("no cache entry found for digest %s and provider %s", dgst, args[1])
+			}
+			return nil
+		},
+	}
+}
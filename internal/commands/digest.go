@@ -0,0 +1,51 @@
+/*
+   Copyright 2020 Docker Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/cli/cli/command"
+	digest "github.com/opencontainers/go-digest"
+
+	"github.com/docker/scan-cli-plugin/internal/registry"
+)
+
+// resolveDigest returns the content digest for image, used as a cache key.
+// For a remote scan it comes straight from the registry manifest fetch; for
+// a local one it is derived from the local daemon's image inspection.
+func resolveDigest(dockerCli command.Cli, image string, remote bool) (digest.Digest, error) {
+	if remote {
+		ref, err := registry.ParseReference(image)
+		if err != nil {
+			return "", err
+		}
+		auth, err := registryAuthConfig(ref.AuthHostname)
+		if err != nil {
+			return "", err
+		}
+		_, dgst, err := registry.NewClient().Manifest(ref, auth)
+		return dgst, err
+	}
+
+	_, raw, err := dockerCli.Client().ImageInspectWithRaw(context.Background(), image)
+	if err != nil {
+		return "", fmt.Errorf("could not inspect local image %q: %w", image, err)
+	}
+	return digest.FromBytes(raw), nil
+}
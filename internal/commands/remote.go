@@ -0,0 +1,54 @@
+/*
+   Copyright 2020 Docker Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package commands
+
+import (
+	"path/filepath"
+
+	cliConfig "github.com/docker/cli/cli/config"
+	"github.com/docker/docker/api/types"
+	"gopkg.in/square/go-jose.v2"
+
+	"github.com/docker/scan-cli-plugin/internal/authentication"
+	"github.com/docker/scan-cli-plugin/internal/registry"
+)
+
+// registryAuthConfig resolves credentials for hostname through the same
+// credential-helper path the Docker CLI uses. It only relies on
+// Authenticator.RegistryAuthConfig, which does not use the Hub-specific
+// fields NewAuthenticator otherwise takes.
+func registryAuthConfig(hostname string) (types.AuthConfig, error) {
+	return authentication.NewAuthenticator(jose.JSONWebKeySet{}, "").RegistryAuthConfig(hostname)
+}
+
+// pullRemote fetches image straight from its registry, resolving
+// credentials through the same credential-helper path the Docker CLI uses,
+// and returns the path to its unpacked rootfs.
+func pullRemote(image string) (string, error) {
+	ref, err := registry.ParseReference(image)
+	if err != nil {
+		return "", err
+	}
+	auth, err := registryAuthConfig(ref.AuthHostname)
+	if err != nil {
+		return "", err
+	}
+
+	cacheDir := filepath.Join(cliConfig.Dir(), "scan", "cache", "registry")
+	puller := registry.NewPuller(registry.NewClient(), cacheDir)
+	return puller.Pull(ref, auth)
+}
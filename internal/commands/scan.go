@@ -0,0 +1,208 @@
+/*
+   Copyright 2020 Docker Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/docker/cli/cli/command"
+	"github.com/spf13/cobra"
+
+	"github.com/docker/scan-cli-plugin/config"
+	"github.com/docker/scan-cli-plugin/internal/cache"
+	"github.com/docker/scan-cli-plugin/internal/format"
+	"github.com/docker/scan-cli-plugin/internal/provider"
+	"github.com/docker/scan-cli-plugin/internal/provider/syft"
+)
+
+// scanOptions holds the flags accepted by the "scan" command.
+type scanOptions struct {
+	sbom     bool
+	format   string
+	provider string
+	remote   bool
+	noCache  bool
+	refresh  bool
+}
+
+// newScanCmd registers the "scan" command's flags and run function onto cmd.
+func newScanCmd(cmd *cobra.Command, dockerCli command.Cli) {
+	opts := scanOptions{}
+	cmd.Flags().BoolVar(&opts.sbom, "sbom", false, "Generate a software bill of materials instead of scanning for vulnerabilities")
+	cmd.Flags().StringVar(&opts.provider, "provider", "", "Scan provider to use (as configured in the \"providers\" section of the scan configuration file)")
+	cmd.Flags().StringVar(&opts.format, "format", "", "Output format: sarif, json (scan) or spdx-json, cyclonedx-json, syft-json, table (--sbom)")
+	cmd.Flags().BoolVar(&opts.remote, "remote", false, "Fetch the image straight from its registry instead of requiring a local pull")
+	cmd.Flags().BoolVar(&opts.noCache, "no-cache", false, "Don't read or write the scan result cache")
+	cmd.Flags().BoolVar(&opts.refresh, "refresh", false, "Re-run the scan even if a cached result exists")
+	cmd.AddCommand(newCacheCmd())
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("docker scan requires exactly one image argument")
+		}
+		if opts.sbom {
+			f := opts.format
+			if f == "" {
+				f = "spdx-json"
+			}
+			return runSBOM(dockerCli, args[0], f, opts)
+		}
+		return runVulnerabilityScan(dockerCli, args[0], opts)
+	}
+}
+
+func runSBOM(dockerCli command.Cli, image string, format string, opts scanOptions) error {
+	conf, err := config.ReadConfigFile()
+	if err != nil {
+		return err
+	}
+	if conf.SBOMPath == "" {
+		return fmt.Errorf("no syft binary configured, set sbomPath in the scan configuration file")
+	}
+	p := syft.NewProvider(conf.SBOMPath)
+
+	version, err := p.Version()
+	if err != nil {
+		return err
+	}
+
+	var key cache.Key
+	var c cache.ResultCache
+	if !opts.noCache {
+		dgst, err := resolveDigest(dockerCli, image, opts.remote)
+		if err == nil {
+			key = cache.Key{Digest: dgst, Provider: "syft", ProviderVersion: version, Options: "sbom:" + format}
+			c = resultCache()
+		}
+	}
+
+	if c != nil && !opts.refresh {
+		if out, ok := c.Get(key); ok {
+			_, err := dockerCli.Out().Write(out)
+			return err
+		}
+	}
+
+	var out []byte
+	if opts.remote {
+		rootfs, pullErr := pullRemote(image)
+		if pullErr != nil {
+			return pullErr
+		}
+		out, err = p.SBOMRootFS(rootfs, format)
+	} else {
+		out, err = p.SBOM(image, format)
+	}
+	if err != nil {
+		return err
+	}
+
+	if c != nil {
+		_ = c.Set(key, out, cache.DefaultTTL)
+	}
+
+	_, err = dockerCli.Out().Write(out)
+	return err
+}
+
+func runVulnerabilityScan(dockerCli command.Cli, image string, opts scanOptions) error {
+	if opts.provider == "" {
+		return fmt.Errorf("--provider is required, e.g. --provider trivy")
+	}
+
+	conf, err := config.ReadConfigFile()
+	if err != nil {
+		return err
+	}
+	paths := map[string]string{}
+	for name, p := range conf.Providers {
+		paths[name] = p.Path
+	}
+	registry := provider.NewRegistry(paths, dockerCli.Err())
+	p, err := registry.Get(opts.provider)
+	if err != nil {
+		return err
+	}
+
+	version, err := p.Version()
+	if err != nil {
+		return err
+	}
+
+	var key cache.Key
+	var c cache.ResultCache
+	if !opts.noCache {
+		dgst, err := resolveDigest(dockerCli, image, opts.remote)
+		if err == nil {
+			key = cache.Key{Digest: dgst, Provider: opts.provider, ProviderVersion: version, Options: "scan"}
+			c = resultCache()
+		}
+	}
+
+	var result provider.ScanResult
+	cached := false
+	if c != nil && !opts.refresh {
+		if buf, ok := c.Get(key); ok {
+			if err := json.Unmarshal(buf, &result); err == nil {
+				cached = true
+			}
+		}
+	}
+
+	if !cached {
+		if opts.remote {
+			rootfs, err := pullRemote(image)
+			if err != nil {
+				return err
+			}
+			result, err = p.ScanRootFS(rootfs)
+			if err != nil {
+				return err
+			}
+			result.Image = image
+		} else {
+			result, err = p.Scan(image)
+			if err != nil {
+				return err
+			}
+		}
+		result.Provider = opts.provider
+		result.ProviderVersion = version
+
+		if c != nil {
+			if buf, err := json.Marshal(result); err == nil {
+				_ = c.Set(key, buf, cache.DefaultTTL)
+			}
+		}
+	}
+
+	var out []byte
+	switch opts.format {
+	case "", "sarif":
+		out, err = format.SARIF(result)
+	case "json":
+		out, err = format.JSON(result)
+	default:
+		return fmt.Errorf("unsupported format %q, expected sarif or json", opts.format)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = dockerCli.Out().Write(out)
+	return err
+}
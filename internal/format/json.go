@@ -0,0 +1,30 @@
+/*
+   Copyright 2020 Docker Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package format serializes a provider.ScanResult into a structured report
+// format consumable by other tooling.
+package format
+
+import (
+	"encoding/json"
+
+	"github.com/docker/scan-cli-plugin/internal/provider"
+)
+
+// JSON marshals result as an indented JSON document.
+func JSON(result provider.ScanResult) ([]byte, error) {
+	return json.MarshalIndent(result, "", "  ")
+}
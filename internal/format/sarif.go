@@ -0,0 +1,127 @@
+/*
+   Copyright 2020 Docker Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/docker/scan-cli-plugin/internal/provider"
+)
+
+const sarifSchema = "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json"
+
+// sarifLog is the subset of the SARIF 2.1.0 object model this plugin emits.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Version        string      `json:"version,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// SARIF marshals result as a SARIF 2.1.0 log.
+func SARIF(result provider.ScanResult) ([]byte, error) {
+	rules := make([]sarifRule, 0, len(result.Vulnerabilities))
+	results := make([]sarifResult, 0, len(result.Vulnerabilities))
+	seen := map[string]bool{}
+	for _, v := range result.Vulnerabilities {
+		if !seen[v.ID] {
+			seen[v.ID] = true
+			rules = append(rules, sarifRule{ID: v.ID, Name: v.Title})
+		}
+		results = append(results, sarifResult{
+			RuleID: v.ID,
+			Level:  sarifLevel(v.Severity),
+			Message: sarifMessage{
+				Text: fmt.Sprintf("%s affects %s %s (fixed in %s)", v.ID, v.Package, v.Version, v.FixedIn),
+			},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: result.Image},
+				},
+			}},
+		})
+	}
+
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:    result.Provider,
+				Version: result.ProviderVersion,
+				Rules:   rules,
+			}},
+			Results: results,
+		}},
+	}
+	return json.MarshalIndent(log, "", "  ")
+}
+
+func sarifLevel(s provider.Severity) string {
+	switch s {
+	case provider.SeverityCritical, provider.SeverityHigh:
+		return "error"
+	case provider.SeverityMedium:
+		return "warning"
+	default:
+		return "note"
+	}
+}
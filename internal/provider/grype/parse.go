@@ -0,0 +1,94 @@
+/*
+   Copyright 2020 Docker Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package grype
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/docker/scan-cli-plugin/internal/provider"
+)
+
+// report is the subset of grype's native JSON report this plugin reads.
+type report struct {
+	Matches []struct {
+		Vulnerability struct {
+			ID       string `json:"id"`
+			Severity string `json:"severity"`
+			Fix      struct {
+				Versions []string `json:"versions"`
+			} `json:"fix"`
+		} `json:"vulnerability"`
+		Artifact struct {
+			Name      string `json:"name"`
+			Version   string `json:"version"`
+			PURL      string `json:"purl"`
+			Locations []struct {
+				LayerID string `json:"layerID"`
+			} `json:"locations"`
+		} `json:"artifact"`
+	} `json:"matches"`
+}
+
+// parse normalizes grype's native JSON report into a provider.ScanResult.
+func parse(buf []byte, image string) (provider.ScanResult, error) {
+	var r report
+	if err := json.Unmarshal(buf, &r); err != nil {
+		return provider.ScanResult{}, fmt.Errorf("could not parse grype report: %w", err)
+	}
+
+	result := provider.ScanResult{
+		Image:    image,
+		Provider: "grype",
+	}
+	for _, m := range r.Matches {
+		fixedIn := ""
+		if len(m.Vulnerability.Fix.Versions) > 0 {
+			fixedIn = strings.Join(m.Vulnerability.Fix.Versions, ", ")
+		}
+		layer := ""
+		if len(m.Artifact.Locations) > 0 {
+			layer = m.Artifact.Locations[0].LayerID
+		}
+		result.Vulnerabilities = append(result.Vulnerabilities, provider.Vulnerability{
+			ID:       m.Vulnerability.ID,
+			Severity: normalizeSeverity(m.Vulnerability.Severity),
+			Package:  m.Artifact.Name,
+			PURL:     m.Artifact.PURL,
+			Version:  m.Artifact.Version,
+			FixedIn:  fixedIn,
+			Layer:    layer,
+		})
+	}
+	return result, nil
+}
+
+func normalizeSeverity(s string) provider.Severity {
+	switch strings.ToLower(s) {
+	case "critical":
+		return provider.SeverityCritical
+	case "high":
+		return provider.SeverityHigh
+	case "medium":
+		return provider.SeverityMedium
+	case "low":
+		return provider.SeverityLow
+	default:
+		return provider.SeverityUnknown
+	}
+}
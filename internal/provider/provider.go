@@ -3,5 +3,54 @@ package provider
 // Provider abstracts a scan provider
 type Provider interface {
 	Version() (string, error)
-	Scan(image string) error
+	// Scan runs a vulnerability scan against image and returns a normalized result.
+	Scan(image string) (ScanResult, error)
+	// SBOM generates a software bill of materials for image in the given
+	// format ("spdx-json", "cyclonedx-json", "syft-json" or "table") and
+	// returns the raw provider output.
+	SBOM(image string, format string) ([]byte, error)
+	// ScanRootFS runs a vulnerability scan against an already unpacked image
+	// rootfs directory, for scanning a remote reference without pulling it
+	// into the local Docker daemon first.
+	ScanRootFS(path string) (ScanResult, error)
+	// SBOMRootFS is the rootfs-based counterpart of SBOM.
+	SBOMRootFS(path string, format string) ([]byte, error)
+}
+
+// Severity is the normalized severity level of a Vulnerability.
+type Severity string
+
+// Supported severities, ordered from least to most severe.
+const (
+	SeverityUnknown  Severity = "unknown"
+	SeverityLow      Severity = "low"
+	SeverityMedium   Severity = "medium"
+	SeverityHigh     Severity = "high"
+	SeverityCritical Severity = "critical"
+)
+
+// Vulnerability is a single finding normalized across providers.
+type Vulnerability struct {
+	ID       string
+	Title    string
+	Severity Severity
+	// Package is the human-readable name of the affected package.
+	Package string
+	// PURL is the package URL (https://github.com/package-url/purl-spec)
+	// identifying the affected package, when the provider supplies one.
+	PURL    string
+	Version string
+	FixedIn string
+	// Layer is the image layer digest the vulnerable package originates
+	// from, when the provider can attribute it.
+	Layer string
+}
+
+// ScanResult is the normalized output of a Provider.Scan call, independent
+// of the underlying provider's native report format.
+type ScanResult struct {
+	Image           string
+	Provider        string
+	ProviderVersion string
+	Vulnerabilities []Vulnerability
 }
@@ -0,0 +1,67 @@
+/*
+   Copyright 2020 Docker Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package provider
+
+import (
+	"fmt"
+	"io"
+)
+
+// Factory builds a Provider configured to run the binary at path.
+type Factory func(path string) Provider
+
+// factories lists the providers known to the registry, keyed by the name
+// used in the "providers" section of the scan configuration file.
+var factories = map[string]Factory{}
+
+// Register adds a named provider factory to the registry. It is expected to
+// be called from the init() function of each internal/provider/<name> package.
+func Register(name string, factory Factory) {
+	factories[name] = factory
+}
+
+// Registry holds the providers configured in config.Config, keyed by name.
+type Registry struct {
+	providers map[string]Provider
+}
+
+// NewRegistry builds a Registry from a map of provider name to binary path,
+// as read from the "providers" section of the scan configuration file. An
+// unknown provider name is skipped with a warning written to warnOut rather
+// than failing the whole registry, so a single stale or typo'd entry doesn't
+// take down every correctly-configured provider.
+func NewRegistry(paths map[string]string, warnOut io.Writer) *Registry {
+	providers := map[string]Provider{}
+	for name, path := range paths {
+		factory, ok := factories[name]
+		if !ok {
+			fmt.Fprintf(warnOut, "docker scan: ignoring unknown provider %q in the scan configuration file\n", name)
+			continue
+		}
+		providers[name] = factory(path)
+	}
+	return &Registry{providers: providers}
+}
+
+// Get returns the named provider, or an error if it was not configured.
+func (r *Registry) Get(name string) (Provider, error) {
+	p, ok := r.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("provider %q is not configured, add it to the \"providers\" section of the scan configuration file", name)
+	}
+	return p, nil
+}
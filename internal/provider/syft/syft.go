@@ -0,0 +1,104 @@
+/*
+   Copyright 2020 Docker Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package syft implements a provider.Provider backed by a locally installed
+// syft binary, producing SBOMs rather than vulnerability reports.
+package syft
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/docker/scan-cli-plugin/internal/provider"
+)
+
+// supportedFormats lists the syft "-o" values this provider accepts.
+var supportedFormats = map[string]bool{
+	"spdx-json":      true,
+	"cyclonedx-json": true,
+	"syft-json":      true,
+	"table":          true,
+}
+
+// Provider shells out to a local syft binary to generate SBOMs.
+type Provider struct {
+	path string
+}
+
+// NewProvider returns a syft-backed Provider using the binary at path.
+func NewProvider(path string) *Provider {
+	return &Provider{path: path}
+}
+
+func init() {
+	provider.Register("syft", func(path string) provider.Provider { return NewProvider(path) })
+}
+
+// Version returns the version of the configured syft binary.
+func (p *Provider) Version() (string, error) {
+	buf, err := p.run("version")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// Scan is unsupported by the syft provider: syft only produces SBOMs, it
+// does not evaluate vulnerabilities.
+func (p *Provider) Scan(image string) (provider.ScanResult, error) {
+	return provider.ScanResult{}, fmt.Errorf("the syft provider does not support vulnerability scanning, use --sbom instead")
+}
+
+// ScanRootFS is unsupported for the same reason as Scan.
+func (p *Provider) ScanRootFS(path string) (provider.ScanResult, error) {
+	return provider.ScanResult{}, fmt.Errorf("the syft provider does not support vulnerability scanning, use --sbom instead")
+}
+
+// SBOM generates a software bill of materials for image in the given format.
+func (p *Provider) SBOM(image string, format string) ([]byte, error) {
+	return p.sbom(image, format)
+}
+
+// SBOMRootFS generates a software bill of materials for an already unpacked
+// image rootfs directory, for scanning a remote reference without pulling
+// it into the local Docker daemon first.
+func (p *Provider) SBOMRootFS(path string, format string) ([]byte, error) {
+	return p.sbom("dir:"+path, format)
+}
+
+func (p *Provider) sbom(target string, format string) ([]byte, error) {
+	if !supportedFormats[format] {
+		return nil, fmt.Errorf("unsupported SBOM format %q", format)
+	}
+	buf, err := p.run("packages", target, "-o", format)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (p *Provider) run(args ...string) (*bytes.Buffer, error) {
+	cmd := exec.Command(p.path, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("syft %s failed: %w: %s", strings.Join(args, " "), err, stderr.String())
+	}
+	return &stdout, nil
+}
@@ -0,0 +1,85 @@
+/*
+   Copyright 2020 Docker Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package trivy
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/docker/scan-cli-plugin/internal/provider"
+)
+
+// report is the subset of trivy's native JSON report this plugin reads.
+type report struct {
+	Results []struct {
+		Target          string `json:"Target"`
+		Vulnerabilities []struct {
+			VulnerabilityID  string `json:"VulnerabilityID"`
+			PkgName          string `json:"PkgName"`
+			InstalledVersion string `json:"InstalledVersion"`
+			FixedVersion     string `json:"FixedVersion"`
+			Title            string `json:"Title"`
+			Severity         string `json:"Severity"`
+			Layer            struct {
+				Digest string `json:"Digest"`
+			} `json:"Layer"`
+		} `json:"Vulnerabilities"`
+	} `json:"Results"`
+}
+
+// parse normalizes trivy's native JSON report into a provider.ScanResult.
+func parse(buf []byte, image string) (provider.ScanResult, error) {
+	var r report
+	if err := json.Unmarshal(buf, &r); err != nil {
+		return provider.ScanResult{}, fmt.Errorf("could not parse trivy report: %w", err)
+	}
+
+	result := provider.ScanResult{
+		Image:    image,
+		Provider: "trivy",
+	}
+	for _, target := range r.Results {
+		for _, v := range target.Vulnerabilities {
+			result.Vulnerabilities = append(result.Vulnerabilities, provider.Vulnerability{
+				ID:       v.VulnerabilityID,
+				Title:    v.Title,
+				Severity: normalizeSeverity(v.Severity),
+				Package:  v.PkgName,
+				Version:  v.InstalledVersion,
+				FixedIn:  v.FixedVersion,
+				Layer:    v.Layer.Digest,
+			})
+		}
+	}
+	return result, nil
+}
+
+func normalizeSeverity(s string) provider.Severity {
+	switch strings.ToUpper(s) {
+	case "CRITICAL":
+		return provider.SeverityCritical
+	case "HIGH":
+		return provider.SeverityHigh
+	case "MEDIUM":
+		return provider.SeverityMedium
+	case "LOW":
+		return provider.SeverityLow
+	default:
+		return provider.SeverityUnknown
+	}
+}
@@ -0,0 +1,93 @@
+/*
+   Copyright 2020 Docker Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package trivy implements a provider.Provider backed by a locally
+// installed trivy binary.
+package trivy
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/docker/scan-cli-plugin/internal/provider"
+)
+
+// Provider shells out to a local trivy binary.
+type Provider struct {
+	path string
+}
+
+// NewProvider returns a trivy-backed Provider using the binary at path.
+func NewProvider(path string) *Provider {
+	return &Provider{path: path}
+}
+
+func init() {
+	provider.Register("trivy", func(path string) provider.Provider { return NewProvider(path) })
+}
+
+// Version returns the version of the configured trivy binary.
+func (p *Provider) Version() (string, error) {
+	buf, err := p.run("--version")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// Scan runs a trivy image scan and returns a normalized result.
+func (p *Provider) Scan(image string) (provider.ScanResult, error) {
+	buf, err := p.run("image", "--format", "json", image)
+	if err != nil {
+		return provider.ScanResult{}, err
+	}
+	return parse(buf.Bytes(), image)
+}
+
+// ScanRootFS runs a trivy scan against an already unpacked image rootfs
+// directory, for scanning a remote reference without pulling it into the
+// local Docker daemon first.
+func (p *Provider) ScanRootFS(path string) (provider.ScanResult, error) {
+	buf, err := p.run("rootfs", "--format", "json", path)
+	if err != nil {
+		return provider.ScanResult{}, err
+	}
+	return parse(buf.Bytes(), path)
+}
+
+// SBOM is unsupported by the trivy provider in this plugin: SBOM generation
+// is handled by the dedicated syft provider.
+func (p *Provider) SBOM(image string, format string) ([]byte, error) {
+	return nil, fmt.Errorf("the trivy provider does not support SBOM generation, use a syft provider instead")
+}
+
+// SBOMRootFS is unsupported for the same reason as SBOM.
+func (p *Provider) SBOMRootFS(path string, format string) ([]byte, error) {
+	return nil, fmt.Errorf("the trivy provider does not support SBOM generation, use a syft provider instead")
+}
+
+func (p *Provider) run(args ...string) (*bytes.Buffer, error) {
+	cmd := exec.Command(p.path, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("trivy %s failed: %w: %s", strings.Join(args, " "), err, stderr.String())
+	}
+	return &stdout, nil
+}
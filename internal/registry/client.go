@@ -0,0 +1,302 @@
+/*
+   Copyright 2020 Docker Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"runtime"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	digest "github.com/opencontainers/go-digest"
+)
+
+// Manifest media types this client knows how to request and decode.
+const (
+	mediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	mediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	mediaTypeOCIManifest        = "application/vnd.oci.image.manifest.v1+json"
+	mediaTypeOCIIndex           = "application/vnd.oci.image.index.v1+json"
+)
+
+// Descriptor references a blob by digest, mirroring the OCI image-spec.
+type Descriptor struct {
+	MediaType string        `json:"mediaType"`
+	Digest    digest.Digest `json:"digest"`
+	Size      int64         `json:"size"`
+}
+
+// Manifest is the subset of an OCI/Docker image manifest this plugin needs
+// to fetch an image's config and layer blobs.
+type Manifest struct {
+	MediaType string       `json:"mediaType"`
+	Config    Descriptor   `json:"config"`
+	Layers    []Descriptor `json:"layers"`
+}
+
+// manifestList is the subset of an OCI image index / Docker manifest list
+// this plugin needs to pick the manifest matching the local platform.
+type manifestList struct {
+	MediaType string `json:"mediaType"`
+	Manifests []struct {
+		Descriptor
+		Platform struct {
+			Architecture string `json:"architecture"`
+			OS           string `json:"os"`
+			Variant      string `json:"variant"`
+		} `json:"platform"`
+	} `json:"manifests"`
+}
+
+func isIndexMediaType(mediaType string) bool {
+	return mediaType == mediaTypeDockerManifestList || mediaType == mediaTypeOCIIndex
+}
+
+// AuthConfig supplies credentials for a registry.
+type AuthConfig = types.AuthConfig
+
+// Client talks to registries over the OCI distribution spec, taking the
+// registry to contact from the Reference passed to each call and
+// transparently negotiating the Bearer token challenge used by Docker Hub,
+// ECR, ACR, GCR and most other registries.
+type Client struct {
+	httpClient *http.Client
+}
+
+// NewClient returns a Client using a default *http.Client.
+func NewClient() *Client {
+	return &Client{httpClient: &http.Client{}}
+}
+
+// Manifest fetches and decodes the manifest for ref, returning its digest
+// alongside the decoded content. If ref resolves to a manifest list / image
+// index (the norm for multi-arch images), it is resolved to the child
+// manifest matching the local platform.
+func (c *Client) Manifest(ref Reference, auth AuthConfig) (Manifest, digest.Digest, error) {
+	mediaType, buf, dgst, err := c.fetchManifest(ref, ref.Identifier, auth)
+	if err != nil {
+		return Manifest{}, "", err
+	}
+
+	if isIndexMediaType(mediaType) {
+		var list manifestList
+		if err := json.Unmarshal(buf, &list); err != nil {
+			return Manifest{}, "", fmt.Errorf("could not parse manifest list for %s: %w", ref.Repository, err)
+		}
+		child, err := selectPlatformManifest(list)
+		if err != nil {
+			return Manifest{}, "", err
+		}
+		_, buf, dgst, err = c.fetchManifest(ref, child.Digest.String(), auth)
+		if err != nil {
+			return Manifest{}, "", err
+		}
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(buf, &m); err != nil {
+		return Manifest{}, "", fmt.Errorf("could not parse manifest for %s: %w", ref.Repository, err)
+	}
+	return m, dgst, nil
+}
+
+// fetchManifest fetches the raw manifest at identifier (a tag or digest),
+// returning its media type, raw body and content digest.
+func (c *Client) fetchManifest(ref Reference, identifier string, auth AuthConfig) (string, []byte, digest.Digest, error) {
+	reqURL := fmt.Sprintf("https://%s/v2/%s/manifests/%s", ref.Hostname, ref.Repository, identifier)
+	req, err := http.NewRequest(http.MethodGet, reqURL, nil)
+	if err != nil {
+		return "", nil, "", err
+	}
+	req.Header.Set("Accept", strings.Join([]string{
+		mediaTypeDockerManifest, mediaTypeDockerManifestList, mediaTypeOCIManifest, mediaTypeOCIIndex,
+	}, ", "))
+
+	resp, err := c.do(req, ref, auth)
+	if err != nil {
+		return "", nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, "", fmt.Errorf("GET %s: unexpected status %s", reqURL, resp.Status)
+	}
+
+	buf, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", nil, "", err
+	}
+
+	var typed struct {
+		MediaType string `json:"mediaType"`
+	}
+	if err := json.Unmarshal(buf, &typed); err != nil {
+		return "", nil, "", fmt.Errorf("could not parse manifest for %s: %w", reqURL, err)
+	}
+
+	dgst := digest.Digest(resp.Header.Get("Docker-Content-Digest"))
+	if dgst == "" {
+		dgst = digest.FromBytes(buf)
+	}
+	return typed.MediaType, buf, dgst, nil
+}
+
+// selectPlatformManifest picks the manifest list entry matching the local
+// platform, the same default the Docker CLI applies when pulling a
+// multi-arch image. An entry whose variant equals localVariant() is
+// preferred, so e.g. an arm/v7 host gets the arm/v7 manifest when one is
+// listed; otherwise the first entry for the local OS and architecture is
+// returned, whatever its variant.
+func selectPlatformManifest(list manifestList) (Descriptor, error) {
+	variant := localVariant()
+	var fallback *Descriptor
+	for i, m := range list.Manifests {
+		if m.Platform.OS != runtime.GOOS || m.Platform.Architecture != runtime.GOARCH {
+			continue
+		}
+		if m.Platform.Variant == variant {
+			return m.Descriptor, nil
+		}
+		if fallback == nil {
+			fallback = &list.Manifests[i].Descriptor
+		}
+	}
+	if fallback != nil {
+		return *fallback, nil
+	}
+	return Descriptor{}, fmt.Errorf("no manifest found for platform %s/%s", runtime.GOOS, runtime.GOARCH)
+}
+
+// localVariant returns the OCI platform variant for the local architecture,
+// e.g. "v7" on 32-bit arm, matching what Docker builds/publishes.
+func localVariant() string {
+	if runtime.GOARCH == "arm" {
+		return "v7"
+	}
+	return ""
+}
+
+// Blob streams the blob identified by dgst. The caller must close it.
+func (c *Client) Blob(ref Reference, dgst digest.Digest, auth AuthConfig) (io.ReadCloser, error) {
+	reqURL := fmt.Sprintf("https://%s/v2/%s/blobs/%s", ref.Hostname, ref.Repository, dgst)
+	req, err := http.NewRequest(http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.do(req, ref, auth)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: unexpected status %s", reqURL, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// do performs req, retrying once with a negotiated Bearer token if the
+// registry challenges the initial anonymous request.
+func (c *Client) do(req *http.Request, ref Reference, auth AuthConfig) (*http.Response, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		return resp, nil
+	}
+	challenge := resp.Header.Get("Www-Authenticate")
+	resp.Body.Close()
+
+	token, err := c.negotiateToken(challenge, auth)
+	if err != nil {
+		return nil, fmt.Errorf("could not authenticate against %s: %w", ref.Hostname, err)
+	}
+	authed := req.Clone(req.Context())
+	authed.Header.Set("Authorization", "Bearer "+token)
+	return c.httpClient.Do(authed)
+}
+
+// negotiateToken exchanges a "Bearer realm=...,service=...,scope=..."
+// challenge for a short-lived access token, as described by the OCI
+// distribution spec's authorization model.
+func (c *Client) negotiateToken(challenge string, auth AuthConfig) (string, error) {
+	params, err := parseBearerChallenge(challenge)
+	if err != nil {
+		return "", err
+	}
+	realm, ok := params["realm"]
+	if !ok {
+		return "", fmt.Errorf("malformed Www-Authenticate header %q: missing realm", challenge)
+	}
+
+	q := url.Values{}
+	if service, ok := params["service"]; ok {
+		q.Set("service", service)
+	}
+	if scope, ok := params["scope"]; ok {
+		q.Set("scope", scope)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, realm+"?"+q.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	if auth.Username != "" {
+		req.SetBasicAuth(auth.Username, auth.Password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GET %s: unexpected status %s", realm, resp.Status)
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("could not decode token response: %w", err)
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	return body.AccessToken, nil
+}
+
+// parseBearerChallenge extracts the key="value" pairs out of a
+// `Bearer realm="...",service="...",scope="..."` Www-Authenticate header.
+func parseBearerChallenge(challenge string) (map[string]string, error) {
+	if !strings.HasPrefix(challenge, "Bearer ") {
+		return nil, fmt.Errorf("unsupported authentication challenge %q", challenge)
+	}
+	params := map[string]string{}
+	for _, part := range strings.Split(strings.TrimPrefix(challenge, "Bearer "), ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		params[kv[0]] = strings.Trim(kv[1], `"`)
+	}
+	return params, nil
+}
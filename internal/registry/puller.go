@@ -0,0 +1,235 @@
+/*
+   Copyright 2020 Docker Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package registry
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	digest "github.com/opencontainers/go-digest"
+)
+
+// Puller fetches a remote image's blobs into a content-addressable cache
+// and unpacks its layers into a rootfs directory, without requiring the
+// image to be pulled into the local Docker daemon first.
+type Puller struct {
+	client   *Client
+	cacheDir string
+}
+
+// NewPuller returns a Puller that caches blobs and unpacked layers under
+// cacheDir.
+func NewPuller(client *Client, cacheDir string) *Puller {
+	return &Puller{client: client, cacheDir: cacheDir}
+}
+
+// Pull resolves ref's manifest and returns the path to a directory
+// containing its unpacked rootfs, downloading and extracting layers only
+// if they are not already present in the cache.
+func (p *Puller) Pull(ref Reference, auth AuthConfig) (string, error) {
+	manifest, dgst, err := p.client.Manifest(ref, auth)
+	if err != nil {
+		return "", err
+	}
+
+	rootfs := filepath.Join(p.cacheDir, "rootfs", dgst.Encoded())
+	if _, err := os.Stat(rootfs); err == nil {
+		return rootfs, nil
+	}
+
+	tmp := rootfs + ".tmp"
+	if err := os.RemoveAll(tmp); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(tmp, 0755); err != nil {
+		return "", err
+	}
+
+	for _, layer := range manifest.Layers {
+		blobPath, err := p.fetchBlob(ref, layer.Digest, auth)
+		if err != nil {
+			return "", err
+		}
+		if err := extractLayer(blobPath, tmp); err != nil {
+			return "", fmt.Errorf("could not extract layer %s: %w", layer.Digest, err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(rootfs), 0755); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp, rootfs); err != nil {
+		return "", err
+	}
+	return rootfs, nil
+}
+
+// fetchBlob returns the local cache path for dgst, downloading it first if
+// it is not already cached. The digest is verified against the downloaded
+// content before it is trusted.
+func (p *Puller) fetchBlob(ref Reference, dgst digest.Digest, auth AuthConfig) (string, error) {
+	path := filepath.Join(p.cacheDir, "blobs", dgst.Algorithm().String(), dgst.Encoded())
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	rc, err := p.client.Blob(ref, dgst, auth)
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return "", err
+	}
+
+	verifier := dgst.Verifier()
+	_, copyErr := io.Copy(io.MultiWriter(f, verifier), rc)
+	closeErr := f.Close()
+	if copyErr != nil {
+		os.Remove(tmp)
+		return "", copyErr
+	}
+	if closeErr != nil {
+		os.Remove(tmp)
+		return "", closeErr
+	}
+	if !verifier.Verified() {
+		os.Remove(tmp)
+		return "", fmt.Errorf("digest mismatch fetching blob %s", dgst)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// OverlayFS whiteout conventions used by Docker/OCI layers to record
+// deletions of files (or whole directories) present in a lower layer.
+const (
+	whiteoutPrefix = ".wh."
+	whiteoutOpaque = ".wh..wh..opq"
+)
+
+// extractLayer unpacks the (optionally gzip-compressed) tar archive at
+// tarPath into dest, honoring OverlayFS whiteout entries so that files
+// deleted in a later layer don't survive in the unpacked rootfs.
+func extractLayer(tarPath string, dest string) error {
+	f, err := os.Open(tarPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if gz, err := gzip.NewReader(f); err == nil {
+		defer gz.Close()
+		r = gz
+	} else if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dest, filepath.Clean(hdr.Name))
+		if !strings.HasPrefix(target, filepath.Clean(dest)+string(os.PathSeparator)) {
+			return fmt.Errorf("invalid layer entry %q escapes rootfs", hdr.Name)
+		}
+
+		base := filepath.Base(target)
+		dir := filepath.Dir(target)
+		if base == whiteoutOpaque {
+			if err := clearDir(dir); err != nil {
+				return fmt.Errorf("could not apply opaque whiteout for %q: %w", hdr.Name, err)
+			}
+			continue
+		}
+		if strings.HasPrefix(base, whiteoutPrefix) {
+			deleted := filepath.Join(dir, strings.TrimPrefix(base, whiteoutPrefix))
+			if err := os.RemoveAll(deleted); err != nil {
+				return fmt.Errorf("could not apply whiteout for %q: %w", hdr.Name, err)
+			}
+			continue
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, os.FileMode(hdr.Mode)); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			out, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.FileMode(hdr.Mode))
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(out, tr); err != nil {
+				out.Close()
+				return err
+			}
+			out.Close()
+		case tar.TypeSymlink:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			_ = os.Remove(target)
+			if err := os.Symlink(hdr.Linkname, target); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// clearDir removes dir's existing contents (from layers extracted so far)
+// without removing dir itself, implementing the opaque whiteout marker's
+// "this directory's lower-layer contents no longer apply" semantics.
+func clearDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
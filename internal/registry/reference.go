@@ -0,0 +1,101 @@
+/*
+   Copyright 2020 Docker Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package registry resolves and fetches images straight from an OCI
+// distribution-spec registry, without requiring the image to be present in
+// the local Docker daemon.
+package registry
+
+import (
+	"github.com/docker/distribution/reference"
+)
+
+// legacyDefaultDomain and defaultDomain mirror how reference.Domain
+// normalizes Docker Hub images, defaultV2Registry is the actual
+// distribution-spec endpoint Hub serves on (as opposed to the registry's
+// normalized display domain), and defaultIndexServer is where the Docker CLI
+// keys Hub credentials in its configuration file.
+const (
+	legacyDefaultDomain = "index.docker.io"
+	defaultDomain       = "docker.io"
+	defaultV2Registry   = "registry-1.docker.io"
+	defaultIndexServer  = "https://index.docker.io/v1/"
+)
+
+// Reference identifies an image on a remote registry.
+type Reference struct {
+	// Hostname is the registry's hostname, e.g. "registry-1.docker.io".
+	Hostname string
+	// AuthHostname is the key under which the Docker CLI stores credentials
+	// for this registry. It matches Hostname except for Docker Hub, whose
+	// credentials are keyed by the legacy index server URL rather than the
+	// distribution-spec API hostname.
+	AuthHostname string
+	// Repository is the repository path, e.g. "library/alpine".
+	Repository string
+	// Identifier is either a tag (e.g. "latest") or a digest
+	// (e.g. "sha256:...") depending on how the image was referenced.
+	Identifier string
+}
+
+// ParseReference parses an image name (with an optional tag or digest) into
+// a Reference, defaulting to the "latest" tag and Docker Hub the same way
+// the Docker CLI does.
+func ParseReference(image string) (Reference, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return Reference{}, err
+	}
+	named = reference.TagNameOnly(named)
+
+	domain := reference.Domain(named)
+	ref := Reference{
+		Hostname:     apiHostname(domain),
+		AuthHostname: authHostname(domain),
+		Repository:   reference.Path(named),
+	}
+	switch v := named.(type) {
+	case reference.Canonical:
+		ref.Identifier = v.Digest().String()
+	case reference.Tagged:
+		ref.Identifier = v.Tag()
+	}
+	return ref, nil
+}
+
+// apiHostname maps a normalized reference domain to the hostname that
+// actually serves the distribution-spec API, since Docker Hub's API
+// endpoint differs from its normalized display domain.
+func apiHostname(domain string) string {
+	switch domain {
+	case defaultDomain, legacyDefaultDomain:
+		return defaultV2Registry
+	default:
+		return domain
+	}
+}
+
+// authHostname maps a normalized reference domain to the key the Docker CLI
+// stores its credentials under. Every registry but Hub keys credentials by
+// plain hostname; Hub keys them by its legacy index server URL.
+func authHostname(domain string) string {
+	switch domain {
+	case defaultDomain, legacyDefaultDomain:
+		return defaultIndexServer
+	default:
+		return domain
+	}
+}